@@ -2,16 +2,30 @@
 package gcore
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gcoreSDK "github.com/G-Core/gcore-dns-sdk-go"
 	"github.com/libdns/libdns"
 )
 
+const (
+	defaultMaxRetries     = 4
+	defaultRetryBaseDelay = 250 * time.Millisecond
+	defaultRetryMaxDelay  = 8 * time.Second
+)
+
 // qualityRecordNames takes a libdns.Record and a zone, and returns a new record with a name that is fully qualified
 // (i.e. it includes the zone name). If the record name does not end with the zone name and a '.', the zone name and '.'
 // are appended to the record name. Otherwise the record name is left unchanged.
@@ -22,48 +36,79 @@ func qualityRecordNames(record libdns.Record, zone string) libdns.Record {
 		return record
 	}
 
+	// record.RR() doesn't preserve ProviderData (see the libdns package
+	// docs), so it's read off the original, still-concrete record here and
+	// threaded through to the rebuilt struct below.
+	providerData := recordProviderData(record)
+
 	if addr, isAddress := rr.(libdns.Address); isAddress {
 		return libdns.Address{
-			Name: libdns.AbsoluteName(addr.Name, zone),
-			TTL:  addr.TTL,
-			IP:   addr.IP,
+			Name:         libdns.AbsoluteName(addr.Name, zone),
+			TTL:          addr.TTL,
+			IP:           addr.IP,
+			ProviderData: providerData,
 		}
 	} else if cname, isCNAME := rr.(libdns.CNAME); isCNAME {
 		return libdns.CNAME{
-			Name:   libdns.AbsoluteName(cname.Name, zone),
-			TTL:    cname.TTL,
-			Target: cname.Target,
+			Name:         libdns.AbsoluteName(cname.Name, zone),
+			TTL:          cname.TTL,
+			Target:       cname.Target,
+			ProviderData: providerData,
 		}
 	} else if txt, isPTR := rr.(libdns.TXT); isPTR {
 		return libdns.TXT{
-			Name: libdns.AbsoluteName(txt.Name, zone),
-			TTL:  txt.TTL,
-			Text: txt.Text,
+			Name:         libdns.AbsoluteName(txt.Name, zone),
+			TTL:          txt.TTL,
+			Text:         txt.Text,
+			ProviderData: providerData,
 		}
 	} else if mx, isMX := rr.(libdns.MX); isMX {
 		return libdns.MX{
-			Name:       libdns.AbsoluteName(mx.Name, zone),
-			TTL:        mx.TTL,
-			Preference: mx.Preference,
-			Target:     mx.Target,
+			Name:         libdns.AbsoluteName(mx.Name, zone),
+			TTL:          mx.TTL,
+			Preference:   mx.Preference,
+			Target:       mx.Target,
+			ProviderData: providerData,
 		}
 	} else if ns, isNS := rr.(libdns.NS); isNS {
 		return libdns.NS{
-			Name:   libdns.AbsoluteName(ns.Name, zone),
-			TTL:    ns.TTL,
-			Target: ns.Target,
+			Name:         libdns.AbsoluteName(ns.Name, zone),
+			TTL:          ns.TTL,
+			Target:       ns.Target,
+			ProviderData: providerData,
 		}
 	} else if srv, isSRV := rr.(libdns.SRV); isSRV {
 		return libdns.SRV{
-			Name:      libdns.AbsoluteName(srv.Name, zone),
-			TTL:       srv.TTL,
-			Service:   srv.Service,
-			Transport: srv.Transport,
-			Priority:  srv.Priority,
-			Weight:    srv.Weight,
-			Target:    srv.Target,
-			Port:      srv.Port,
+			Name:         libdns.AbsoluteName(srv.Name, zone),
+			TTL:          srv.TTL,
+			Service:      srv.Service,
+			Transport:    srv.Transport,
+			Priority:     srv.Priority,
+			Weight:       srv.Weight,
+			Target:       srv.Target,
+			Port:         srv.Port,
+			ProviderData: providerData,
 		}
+	} else if caa, isCAA := rr.(libdns.CAA); isCAA {
+		return libdns.CAA{
+			Name:         libdns.AbsoluteName(caa.Name, zone),
+			TTL:          caa.TTL,
+			Flags:        caa.Flags,
+			Tag:          caa.Tag,
+			Value:        caa.Value,
+			ProviderData: providerData,
+		}
+	} else if svc, isServiceBinding := rr.(libdns.ServiceBinding); isServiceBinding {
+		svc.Name = libdns.AbsoluteName(svc.Name, zone)
+		svc.ProviderData = providerData
+		return svc
+	} else if genericRR, isRR := rr.(libdns.RR); isRR {
+		// Record types libdns doesn't parse into a dedicated struct (PTR,
+		// TLSA, SSHFP, ...) come back from Parse() as the opaque RR itself;
+		// its Name still needs qualifying even though there's no
+		// ProviderData field to carry.
+		genericRR.Name = libdns.AbsoluteName(genericRR.Name, zone)
+		return genericRR
 	}
 	log.Printf("[qualifyRecordNames] type: %s name: %s", record.RR().Type, record.RR().Name)
 
@@ -80,82 +125,796 @@ func unqualifyRecordNames(record libdns.Record, zone string) libdns.Record {
 		return record
 	}
 
+	// See the matching comment in qualityRecordNames: ProviderData has to be
+	// read off the original record, since RR()/Parse() don't carry it.
+	providerData := recordProviderData(record)
+
 	if addr, isAddress := rr.(libdns.Address); isAddress {
 		return libdns.Address{
-			Name: libdns.RelativeName(addr.Name, zone),
-			TTL:  addr.TTL,
-			IP:   addr.IP,
+			Name:         libdns.RelativeName(addr.Name, zone),
+			TTL:          addr.TTL,
+			IP:           addr.IP,
+			ProviderData: providerData,
 		}
 	} else if cname, isCNAME := rr.(libdns.CNAME); isCNAME {
 		return libdns.CNAME{
-			Name:   libdns.RelativeName(cname.Name, zone),
-			TTL:    cname.TTL,
-			Target: cname.Target,
+			Name:         libdns.RelativeName(cname.Name, zone),
+			TTL:          cname.TTL,
+			Target:       cname.Target,
+			ProviderData: providerData,
 		}
 	} else if txt, isPTR := rr.(libdns.TXT); isPTR {
 		return libdns.TXT{
-			Name: libdns.RelativeName(txt.Name, zone),
-			TTL:  txt.TTL,
-			Text: txt.Text,
+			Name:         libdns.RelativeName(txt.Name, zone),
+			TTL:          txt.TTL,
+			Text:         txt.Text,
+			ProviderData: providerData,
 		}
 	} else if mx, isMX := rr.(libdns.MX); isMX {
 		return libdns.MX{
-			Name:       libdns.RelativeName(mx.Name, zone),
-			TTL:        mx.TTL,
-			Preference: mx.Preference,
-			Target:     mx.Target,
+			Name:         libdns.RelativeName(mx.Name, zone),
+			TTL:          mx.TTL,
+			Preference:   mx.Preference,
+			Target:       mx.Target,
+			ProviderData: providerData,
 		}
 	} else if ns, isNS := rr.(libdns.NS); isNS {
 		return libdns.NS{
-			Name:   libdns.RelativeName(ns.Name, zone),
-			TTL:    ns.TTL,
-			Target: ns.Target,
+			Name:         libdns.RelativeName(ns.Name, zone),
+			TTL:          ns.TTL,
+			Target:       ns.Target,
+			ProviderData: providerData,
 		}
 	} else if srv, isSRV := rr.(libdns.SRV); isSRV {
 		return libdns.SRV{
-			Name:      libdns.RelativeName(srv.Name, zone),
-			TTL:       srv.TTL,
-			Service:   srv.Service,
-			Transport: srv.Transport,
-			Priority:  srv.Priority,
-			Weight:    srv.Weight,
-			Target:    srv.Target,
-			Port:      srv.Port,
+			Name:         libdns.RelativeName(srv.Name, zone),
+			TTL:          srv.TTL,
+			Service:      srv.Service,
+			Transport:    srv.Transport,
+			Priority:     srv.Priority,
+			Weight:       srv.Weight,
+			Target:       srv.Target,
+			Port:         srv.Port,
+			ProviderData: providerData,
+		}
+	} else if caa, isCAA := rr.(libdns.CAA); isCAA {
+		return libdns.CAA{
+			Name:         libdns.RelativeName(caa.Name, zone),
+			TTL:          caa.TTL,
+			Flags:        caa.Flags,
+			Tag:          caa.Tag,
+			Value:        caa.Value,
+			ProviderData: providerData,
 		}
+	} else if svc, isServiceBinding := rr.(libdns.ServiceBinding); isServiceBinding {
+		svc.Name = libdns.RelativeName(svc.Name, zone)
+		svc.ProviderData = providerData
+		return svc
+	} else if genericRR, isRR := rr.(libdns.RR); isRR {
+		genericRR.Name = libdns.RelativeName(genericRR.Name, zone)
+		return genericRR
 	}
 	log.Printf("[unqualifyRecordNames] type: %s name: %s", record.RR().Type, record.RR().Name)
 
 	return record
 }
 
+// recordProviderData returns the ProviderData a caller attached to record,
+// if record is one of the concrete RR-type structs this package hands back
+// from GetRecords/AppendRecords/SetRecords/DeleteRecords. It returns nil for
+// the opaque libdns.RR type and any record type this package doesn't handle.
+func recordProviderData(record libdns.Record) any {
+	switch rr := record.(type) {
+	case libdns.Address:
+		return rr.ProviderData
+	case libdns.CNAME:
+		return rr.ProviderData
+	case libdns.TXT:
+		return rr.ProviderData
+	case libdns.MX:
+		return rr.ProviderData
+	case libdns.NS:
+		return rr.ProviderData
+	case libdns.SRV:
+		return rr.ProviderData
+	case libdns.CAA:
+		return rr.ProviderData
+	case libdns.ServiceBinding:
+		return rr.ProviderData
+	default:
+		return nil
+	}
+}
+
+// recordMeta returns record's ProviderData as a *RecordMeta, or nil if
+// record carries no ProviderData or ProviderData of another type.
+func recordMeta(record libdns.Record) *RecordMeta {
+	meta, _ := recordProviderData(record).(*RecordMeta)
+	return meta
+}
+
+// RecordMeta is GCore-specific per-record data that the common libdns RR
+// types have no field for: weighted load balancing, geo-based routing
+// (continent/country/ASN restrictions), failover health checks, and the
+// enabled flag GCore tracks per record. GetRecords populates it into
+// ProviderData for any record whose RRSet carries this data; AppendRecords
+// and SetRecords apply it back when an incoming record's ProviderData is a
+// *RecordMeta.
+//
+// Weight, ASN, Continents, Countries, Notes, Backup, Fallback, and Default
+// are stored per record by GCore. Filters and Failover are stored once per
+// RRSet and apply to every record sharing the same (name, type); if more
+// than one record in a call carries them, the last one processed wins.
+type RecordMeta struct {
+	Enabled bool
+
+	Weight     *int
+	ASN        []int
+	Continents []string
+	Countries  []string
+	Notes      []string
+	Backup     bool
+	Fallback   bool
+	Default    bool
+
+	Filters  []gcoreSDK.RecordFilter
+	Failover map[string]any
+}
+
+// withRecordMeta attaches meta to record's ProviderData field, if record is
+// a concrete RR-type struct this package returns and meta is non-nil.
+func withRecordMeta(record libdns.Record, meta *RecordMeta) libdns.Record {
+	if meta == nil {
+		return record
+	}
+
+	switch rr := record.(type) {
+	case libdns.Address:
+		rr.ProviderData = meta
+		return rr
+	case libdns.CNAME:
+		rr.ProviderData = meta
+		return rr
+	case libdns.TXT:
+		rr.ProviderData = meta
+		return rr
+	case libdns.MX:
+		rr.ProviderData = meta
+		return rr
+	case libdns.NS:
+		rr.ProviderData = meta
+		return rr
+	case libdns.SRV:
+		rr.ProviderData = meta
+		return rr
+	case libdns.CAA:
+		rr.ProviderData = meta
+		return rr
+	case libdns.ServiceBinding:
+		rr.ProviderData = meta
+		return rr
+	default:
+		return record
+	}
+}
+
+// recordMetaFromGCore builds a RecordMeta from a GCore RRSet and one of its
+// records, or returns nil if neither carries anything beyond the defaults
+// (an enabled record with no weight, geo, or failover restrictions), so that
+// ordinary records don't carry a ProviderData value at all.
+func recordMetaFromGCore(rrset gcoreSDK.RRSet, record gcoreSDK.ResourceRecord) *RecordMeta {
+	meta := &RecordMeta{
+		Enabled:    record.Enabled,
+		Weight:     metaIntPtr(record.Meta["weight"]),
+		ASN:        metaIntSlice(record.Meta["asn"]),
+		Continents: metaStringSlice(record.Meta["continents"]),
+		Countries:  metaStringSlice(record.Meta["countries"]),
+		Notes:      metaStringSlice(record.Meta["notes"]),
+		Backup:     metaBool(record.Meta["backup"]),
+		Fallback:   metaBool(record.Meta["fallback"]),
+		Default:    metaBool(record.Meta["default"]),
+		Filters:    rrset.Filters,
+	}
+	if failover, ok := rrset.Meta["failover"].(map[string]any); ok {
+		meta.Failover = failover
+	}
+
+	if meta.Enabled && meta.Weight == nil && len(meta.ASN) == 0 && len(meta.Continents) == 0 &&
+		len(meta.Countries) == 0 && len(meta.Notes) == 0 && !meta.Backup && !meta.Fallback && !meta.Default &&
+		len(meta.Filters) == 0 && len(meta.Failover) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// applyRecordMeta copies meta's per-record fields onto rr, and its
+// RRSet-wide Filters/Failover fields onto rrset (see the RecordMeta docs
+// for why those two live at different levels).
+func applyRecordMeta(rr *gcoreSDK.ResourceRecord, rrset *gcoreSDK.RRSet, meta *RecordMeta) {
+	rr.Enabled = meta.Enabled
+
+	rr.Meta = map[string]any{}
+	if meta.Weight != nil {
+		rr.Meta["weight"] = *meta.Weight
+	}
+	if len(meta.ASN) > 0 {
+		rr.Meta["asn"] = meta.ASN
+	}
+	if len(meta.Continents) > 0 {
+		rr.Meta["continents"] = meta.Continents
+	}
+	if len(meta.Countries) > 0 {
+		rr.Meta["countries"] = meta.Countries
+	}
+	if len(meta.Notes) > 0 {
+		rr.Meta["notes"] = meta.Notes
+	}
+	if meta.Backup {
+		rr.Meta["backup"] = true
+	}
+	if meta.Fallback {
+		rr.Meta["fallback"] = true
+	}
+	if meta.Default {
+		rr.Meta["default"] = true
+	}
+	if len(rr.Meta) == 0 {
+		rr.Meta = nil
+	}
+
+	if len(meta.Filters) > 0 {
+		rrset.Filters = meta.Filters
+	}
+	if len(meta.Failover) > 0 {
+		if rrset.Meta == nil {
+			rrset.Meta = gcoreSDK.RRSetMeta{}
+		}
+		rrset.Meta["failover"] = meta.Failover
+	}
+}
+
+// metaIntPtr converts a GCore record Meta value (decoded from JSON, so a
+// float64) to an *int, or nil if v isn't a number.
+func metaIntPtr(v any) *int {
+	f, ok := v.(float64)
+	if !ok {
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+// metaIntSlice converts a GCore record Meta value to a []int.
+func metaIntSlice(v any) []int {
+	raw, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]int, 0, len(raw))
+	for _, x := range raw {
+		if f, ok := x.(float64); ok {
+			out = append(out, int(f))
+		}
+	}
+	return out
+}
+
+// metaStringSlice converts a GCore record Meta value to a []string.
+func metaStringSlice(v any) []string {
+	raw, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, x := range raw {
+		if s, ok := x.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// metaBool converts a GCore record Meta value to a bool.
+func metaBool(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// rrsetKey groups libdns records by the (name, type) pair that identifies a
+// single GCore RRSet, so that a batch of records destined for the same
+// RRSet can be applied with one read and one write.
+type rrsetKey struct {
+	name string
+	typ  string
+}
+
+func (k rrsetKey) String() string {
+	return k.typ + " " + k.name
+}
+
+// groupRRSets buckets records by their RRSet key, preserving the order in
+// which each key was first seen so batches are applied deterministically.
+func groupRRSets(records []libdns.Record) ([]rrsetKey, map[rrsetKey][]libdns.Record) {
+	order := make([]rrsetKey, 0, len(records))
+	groups := make(map[rrsetKey][]libdns.Record, len(records))
+
+	for _, record := range records {
+		key := rrsetKey{name: record.RR().Name, typ: record.RR().Type}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], record)
+	}
+
+	return order, groups
+}
+
+// gcoreContent builds the GCore ResourceRecord.Content value for record.
+// GCore represents some record types (CAA, MX, SRV, SVCB/HTTPS, TLSA,
+// SSHFP) as one element per field rather than a single opaque string, so
+// those need their own encoding; everything else is passed through as
+// record's flattened zone-file data in a single-element slice.
+func gcoreContent(record libdns.Record) []any {
+	switch rr := record.(type) {
+	case libdns.CAA:
+		// libdns.CAA.RR() quotes Value the way a zone file would, but GCore
+		// wants the raw value, so this is built from the typed fields
+		// directly rather than round-tripped through RR().Data.
+		return []any{int64(rr.Flags), rr.Tag, rr.Value}
+	case libdns.MX, libdns.SRV:
+		return gcoreSDK.ContentFromValue(record.RR().Type, record.RR().Data)
+	case libdns.ServiceBinding:
+		// gcoreSDK.ContentFromValue dispatches on record.RR().Type through
+		// ToRecordType, whose switch matches "https" and the SDK's own
+		// "scvb" typo but not "svcb" — a non-HTTPS-scheme ServiceBinding
+		// (RR().Type == "SVCB") would fall through to the opaque-string
+		// encoding instead of the structured one GCore needs. Go straight
+		// to the SDK's structured encoder for both schemes.
+		return gcoreSDK.RecordTypeHTTPS_SCVB(record.RR().Data).ToContent()
+	case libdns.RR:
+		switch rr.Type {
+		case "TLSA":
+			return tlsaContent(rr.Data)
+		case "SSHFP":
+			return sshfpContent(rr.Data)
+		}
+	}
+
+	return []any{record.RR().Data}
+}
+
+// tlsaContent splits a TLSA record's zone-file data ("usage selector
+// matching-type certificate-association-data") into GCore's per-field
+// content slice. If data doesn't parse as TLSA, it's passed through as a
+// single opaque element so the write fails against the API instead of
+// silently here.
+func tlsaContent(data string) []any {
+	parts := strings.SplitN(data, " ", 4)
+	if len(parts) != 4 {
+		return []any{data}
+	}
+
+	usage, err1 := strconv.ParseInt(parts[0], 10, 64)
+	selector, err2 := strconv.ParseInt(parts[1], 10, 64)
+	matchingType, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return []any{data}
+	}
+
+	return []any{usage, selector, matchingType, parts[3]}
+}
+
+// sshfpContent splits an SSHFP record's zone-file data ("algorithm
+// fingerprint-type fingerprint") into GCore's per-field content slice. If
+// data doesn't parse as SSHFP, it's passed through as a single opaque
+// element so the write fails against the API instead of silently here.
+func sshfpContent(data string) []any {
+	parts := strings.SplitN(data, " ", 3)
+	if len(parts) != 3 {
+		return []any{data}
+	}
+
+	algorithm, err1 := strconv.ParseInt(parts[0], 10, 64)
+	fingerprintType, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return []any{data}
+	}
+
+	return []any{algorithm, fingerprintType, parts[2]}
+}
+
+// contentKey returns the string GCore's ContentToString would produce for
+// record's encoded content, so it can be compared against content read back
+// from the API (e.g. to dedupe incoming records or find records to delete)
+// even for types like CAA whose RR().Data differs from GCore's
+// representation.
+func contentKey(record libdns.Record) string {
+	return gcoreSDK.ResourceRecord{Content: gcoreContent(record)}.ContentToString()
+}
+
+// BatchError is returned when a batched call to AppendRecords, SetRecords,
+// or DeleteRecords fails partway through. Group identifies the RRSet
+// ("type name") whose update or delete failed with Err. RollbackErrors, if
+// non-empty, maps the RRSet keys of previously-applied groups in the same
+// call that could not be reverted back to their pre-call state, keyed the
+// same way as Group.
+type BatchError struct {
+	Group          string
+	Err            error
+	RollbackErrors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	if len(e.RollbackErrors) == 0 {
+		return fmt.Sprintf("update rrset %s: %v", e.Group, e.Err)
+	}
+
+	keys := make([]string, 0, len(e.RollbackErrors))
+	for key := range e.RollbackErrors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s: %v", key, e.RollbackErrors[key])
+	}
+
+	return fmt.Sprintf("update rrset %s: %v (rollback incomplete for %d rrset(s): %s)",
+		e.Group, e.Err, len(e.RollbackErrors), strings.Join(parts, "; "))
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// rrsetBatch applies a sequence of RRSet edits to a zone, remembering the
+// pre-edit state of each RRSet it touches so that if a later edit fails,
+// the edits already committed in the same call can be rolled back.
+type rrsetBatch struct {
+	ctx  context.Context
+	cli  *gcoreSDK.Client
+	zone string
+	done []rrsetSnapshot
+}
+
+type rrsetSnapshot struct {
+	key      rrsetKey
+	existed  bool
+	original gcoreSDK.RRSet
+}
+
+func newRRSetBatch(ctx context.Context, cli *gcoreSDK.Client, zone string) *rrsetBatch {
+	return &rrsetBatch{ctx: ctx, cli: cli, zone: zone}
+}
+
+// apply reads the current RRSet for key (which may not exist), asks mutate
+// to compute the desired state, and writes it. Returning a nil RRSet from
+// mutate deletes the RRSet instead of updating it. On failure, apply rolls
+// back every group already applied in this batch before returning.
+func (b *rrsetBatch) apply(key rrsetKey, mutate func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet) error {
+	current, err := b.cli.RRSet(b.ctx, b.zone, key.name, key.typ, -1, 0)
+	existed := err == nil
+	if err != nil && !strings.Contains(err.Error(), "404: record is not found") {
+		return &BatchError{Group: key.String(), Err: err, RollbackErrors: b.rollback()}
+	}
+
+	desired := mutate(current, existed)
+
+	if desired == nil {
+		if !existed {
+			return nil
+		}
+		if err := b.cli.DeleteRRSet(b.ctx, b.zone, key.name, key.typ); err != nil {
+			return &BatchError{Group: key.String(), Err: err, RollbackErrors: b.rollback()}
+		}
+	} else if err := b.cli.UpdateRRSet(b.ctx, b.zone, key.name, key.typ, *desired); err != nil {
+		return &BatchError{Group: key.String(), Err: err, RollbackErrors: b.rollback()}
+	}
+
+	b.done = append(b.done, rrsetSnapshot{key: key, existed: existed, original: current})
+	return nil
+}
+
+// snapshot reads key's current RRSet without writing anything, for callers
+// that apply their own write outside apply (e.g. the ACME detect-and-retry loops)
+// but still want that write covered by this batch's rollback if a later
+// group in the same call fails.
+func (b *rrsetBatch) snapshot(key rrsetKey) (current gcoreSDK.RRSet, existed bool, err error) {
+	current, err = b.cli.RRSet(b.ctx, b.zone, key.name, key.typ, -1, 0)
+	existed = err == nil
+	if err != nil && !strings.Contains(err.Error(), "404: record is not found") {
+		return gcoreSDK.RRSet{}, false, err
+	}
+	return current, existed, nil
+}
+
+// track records key's pre-write state (original/existed) for a write made
+// outside apply, so rollback reverts it along with every group apply itself
+// wrote. Callers track before writing, so a key whose write later fails is
+// rolled back too.
+func (b *rrsetBatch) track(key rrsetKey, existed bool, original gcoreSDK.RRSet) {
+	b.done = append(b.done, rrsetSnapshot{key: key, existed: existed, original: original})
+}
+
+// rollback reverts every group applied so far, in reverse order, restoring
+// groups that existed before the batch and deleting groups that the batch
+// created. It returns the RRSet keys that could not be reverted.
+func (b *rrsetBatch) rollback() map[string]error {
+	var errs map[string]error
+
+	for i := len(b.done) - 1; i >= 0; i-- {
+		snap := b.done[i]
+		var err error
+		if snap.existed {
+			err = b.cli.UpdateRRSet(b.ctx, b.zone, snap.key.name, snap.key.typ, snap.original)
+		} else {
+			err = b.cli.DeleteRRSet(b.ctx, b.zone, snap.key.name, snap.key.typ)
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[snap.key.String()] = err
+		}
+	}
+
+	return errs
+}
+
+// retryTransport wraps an http.RoundTripper with exponential backoff
+// retries for transient GCore API failures: 429 responses (honoring a
+// Retry-After header when present), 5xx responses, and transport-level
+// errors such as dropped connections. It sits underneath every call the
+// SDK client makes, so RRSet, UpdateRRSet, DeleteRRSetRecord, and Zone all
+// benefit without Provider having to wrap each call site individually.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	// The body must be re-readable across attempts, so it's buffered
+	// up front rather than relying on req.GetBody (not all callers set it).
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("buffer request body for retry: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := next.RoundTrip(req)
+		if attempt >= t.maxRetries || !shouldRetryRequest(resp, err) {
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, t.baseDelay, t.maxDelay, resp)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// shouldRetryRequest reports whether a response/error pair from a single
+// attempt is worth retrying: rate limiting, server errors, and transport
+// failures are transient; everything else (4xx, successful responses) is not.
+func shouldRetryRequest(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryDelay computes how long to wait before the next attempt. For a 429
+// with a parseable Retry-After header, that value wins; otherwise it falls
+// back to exponential backoff from baseDelay, capped at maxDelay and
+// jittered so that concurrent callers (e.g. several ACME challenges at
+// once) don't retry in lockstep.
+func retryDelay(attempt int, baseDelay, maxDelay time.Duration, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	delay := baseDelay << attempt
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2+1)))
+}
+
+// parseRetryAfter parses a Retry-After header value, which per RFC 9110 is
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
 // Provider facilitates DNS record manipulation with GCore DNS.
 type Provider struct {
 	APIKey string `json:"api_key,omitempty"`
+
+	// HTTPClient, if set, is used for all requests to the GCore API instead
+	// of the SDK's default client, so callers (Caddy, lego, cert-manager)
+	// can inject their own timeouts, proxies, or tracing transport. Its
+	// Transport is wrapped with retry/backoff handling either way.
+	HTTPClient *http.Client `json:"-"`
+
+	// MaxRetries, RetryBaseDelay, and RetryMaxDelay configure the backoff
+	// applied to failed GCore API requests (see retryTransport). Zero
+	// values fall back to defaultMaxRetries, defaultRetryBaseDelay, and
+	// defaultRetryMaxDelay.
+	MaxRetries     int           `json:"max_retries,omitempty"`
+	RetryBaseDelay time.Duration `json:"retry_base_delay,omitempty"`
+	RetryMaxDelay  time.Duration `json:"retry_max_delay,omitempty"`
+
+	// zonesMu guards zones, following the same pattern libdns/cloudflare
+	// uses to cache zone metadata across calls.
+	zonesMu sync.Mutex
+	zones   map[string]gcoreSDK.Zone
 }
 
-// GetRecords lists all the records in the zone.
-func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+// client builds a GCore SDK client authenticated with APIKey, using
+// p.HTTPClient when set (falling back to the SDK's default otherwise) with
+// its Transport wrapped for retry/backoff handling.
+func (p *Provider) client() *gcoreSDK.Client {
 	cli := gcoreSDK.NewClient(gcoreSDK.PermanentAPIKeyAuth(p.APIKey))
+	if p.HTTPClient != nil {
+		cli.HTTPClient = p.HTTPClient
+	}
+
+	maxRetries, baseDelay, maxDelay := p.retryConfig()
+
+	wrapped := *cli.HTTPClient
+	wrapped.Transport = &retryTransport{
+		next:       cli.HTTPClient.Transport,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+	}
+	cli.HTTPClient = &wrapped
+
+	return cli
+}
+
+// retryConfig returns the effective retry attempt count and backoff bounds
+// for p, applying the defaultMaxRetries/defaultRetryBaseDelay/
+// defaultRetryMaxDelay fallbacks. Used both for the HTTP retry transport
+// and for the ACME detect-and-retry loops below, which hit the same kind of
+// transient failures at the application level instead of the transport
+// level.
+func (p *Provider) retryConfig() (maxRetries int, baseDelay, maxDelay time.Duration) {
+	maxRetries = p.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	baseDelay = p.RetryBaseDelay
+	if baseDelay == 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay = p.RetryMaxDelay
+	if maxDelay == 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	return maxRetries, baseDelay, maxDelay
+}
+
+// zoneInfo returns the GCore zone metadata for zone, serving it from cache
+// when a previous call in this Provider's lifetime already fetched it. This
+// avoids refetching the whole zone (and its record list) on every
+// GetRecords call for the same zone; AppendRecords, SetRecords, and
+// DeleteRecords don't read zone metadata at all.
+func (p *Provider) zoneInfo(ctx context.Context, cli *gcoreSDK.Client, zone string) (gcoreSDK.Zone, error) {
+	p.zonesMu.Lock()
+	cached, ok := p.zones[zone]
+	p.zonesMu.Unlock()
+	if ok {
+		return cached, nil
+	}
 
-	// Get records for zone and convert to libdns records
 	gcoreZone, err := cli.Zone(ctx, zone)
+	if err != nil {
+		return gcoreSDK.Zone{}, err
+	}
+
+	p.zonesMu.Lock()
+	if p.zones == nil {
+		p.zones = make(map[string]gcoreSDK.Zone)
+	}
+	p.zones[zone] = gcoreZone
+	p.zonesMu.Unlock()
+
+	return gcoreZone, nil
+}
+
+// invalidateZone drops any cached metadata for zone, so the next zoneInfo
+// call refetches it. It must be called after any successful write so the
+// cache doesn't serve stale records.
+func (p *Provider) invalidateZone(zone string) {
+	p.zonesMu.Lock()
+	delete(p.zones, zone)
+	p.zonesMu.Unlock()
+}
+
+// GetRecords lists all the records in the zone.
+//
+// gcoreZone.Records (from the cached zone metadata) only carries each
+// record's short answer, not its weight, geo-routing, failover, or enabled
+// metadata — GCore only returns that via a per-(name, type) RRSet call. So
+// GetRecords reads one RRSet per distinct (name, type) pair in the zone
+// rather than one per record, trading the zero-RRSet-call fast path for the
+// ability to round-trip that metadata through ProviderData; see RecordMeta.
+func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	cli := p.client()
+
+	gcoreZone, err := p.zoneInfo(ctx, cli, zone)
 	if err != nil {
 		return nil, err
 	}
 
-	records := make([]libdns.Record, len(gcoreZone.Records))
-	for i, gcoreRecord := range gcoreZone.Records {
-		rrSets, err := cli.RRSet(ctx, zone, gcoreRecord.Name, gcoreRecord.Type, -1, 0)
+	order, seen := make([]rrsetKey, 0, len(gcoreZone.Records)), make(map[rrsetKey]bool, len(gcoreZone.Records))
+	for _, gcoreRecord := range gcoreZone.Records {
+		key := rrsetKey{name: gcoreRecord.Name, typ: gcoreRecord.Type}
+		if !seen[key] {
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+
+	var records []libdns.Record
+	for _, key := range order {
+		rrset, err := cli.RRSet(ctx, zone, key.name, key.typ, -1, 0)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("get rrset %s: %w", key, err)
 		}
-		for _, rrSet := range rrSets.Records {
-			records[i] = libdns.RR{
-				Name: gcoreRecord.Name,
-				Type: gcoreRecord.Type,
-				TTL:  time.Duration(gcoreRecord.TTL) * time.Second,
-				Data: rrSet.ContentToString(),
+
+		for _, gcoreRecord := range rrset.Records {
+			record, err := (libdns.RR{
+				Name: key.name,
+				Type: key.typ,
+				TTL:  time.Duration(rrset.TTL) * time.Second,
+				Data: gcoreRecord.ContentToString(),
+			}).Parse()
+			if err != nil {
+				log.Printf("error parsing record: %v", err)
+				continue
 			}
+
+			records = append(records, withRecordMeta(record, recordMetaFromGCore(rrset, gcoreRecord)))
 		}
 	}
 
@@ -167,62 +926,94 @@ func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record
 }
 
 // AppendRecords adds records to the zone. It returns the records that were added.
+//
+// Records are grouped by their (name, type) RRSet, so adding several records
+// to the same RRSet (e.g. ten A records for the same name) costs one read
+// and one write instead of one pair per record. If a group fails to apply,
+// groups already written earlier in the same call are rolled back; see
+// [BatchError]. A record whose ProviderData is a *RecordMeta has its
+// weight, geo-routing, failover, and enabled metadata applied to the RRSet.
+//
+// A TXT group named "_acme-challenge[.*]" skips the single read-modify-write
+// above and instead goes through appendACMEChallengeTXT's detect-and-retry
+// loop (its pre-call state is still rolled back along with the other
+// groups if it or a later group fails): ACME clients publish DNS-01 challenges for every SAN of a
+// certificate in parallel, all against the same RRSet, and a plain
+// read-modify-write would let one call's write silently clobber another's.
+// GCore's API has no conditional-write primitive, so this narrows that
+// window rather than closing it outright; see appendACMEChallengeTXT.
 func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	cli := gcoreSDK.NewClient(gcoreSDK.PermanentAPIKeyAuth(p.APIKey))
+	cli := p.client()
 
 	for i, record := range records {
 		records[i] = qualityRecordNames(record, zone)
 	}
 
-	recordsByType := make(map[string][]libdns.Record)
-	for _, record := range records {
-		recordsByType[record.RR().Type] = append(recordsByType[record.RR().Type], record)
-	}
+	order, groups := groupRRSets(records)
+	batch := newRRSetBatch(ctx, cli, zone)
 
 	var addedRecords []libdns.Record
 
-	for recordType, records := range recordsByType {
-		for _, record := range records {
-			rrSet, err := cli.RRSet(ctx, zone, record.RR().Name, recordType, -1, 0)
+	for _, key := range order {
+		groupRecords := groups[key]
+
+		if key.typ == "TXT" && isACMEChallengeName(key.name) {
+			original, existed, err := batch.snapshot(key)
 			if err != nil {
-				if strings.Contains(err.Error(), "404: record is not found") {
-					rrSet = gcoreSDK.RRSet{
-						Type: recordType,
-						TTL:  int(record.RR().TTL.Seconds()),
-						Records: []gcoreSDK.ResourceRecord{
-							{
-								Content: []any{record.RR().Data},
-								Enabled: true,
-							},
-						},
-					}
-					if err := cli.UpdateRRSet(ctx, zone, record.RR().Name, recordType, rrSet); err != nil {
-						return nil, err
-					}
-					addedRecords = append(addedRecords, record)
-					continue
-				}
-				return nil, err
+				return nil, &BatchError{Group: key.String(), Err: err, RollbackErrors: batch.rollback()}
+			}
+			// Tracked before the call, not after: appendACMEChallengeTXT's
+			// retry loop can write one or more times and still return an
+			// error (e.g. it gives up on a persistent conflict), and those
+			// writes need to be rolled back same as any other group's.
+			batch.track(key, existed, original)
+			if err := p.appendACMEChallengeTXT(ctx, cli, zone, key, groupRecords); err != nil {
+				return nil, &BatchError{Group: key.String(), Err: err, RollbackErrors: batch.rollback()}
 			}
+			addedRecords = append(addedRecords, groupRecords...)
+			continue
+		}
 
-			for _, rr := range rrSet.Records {
-				if rr.ContentToString() == record.RR().Data {
-					continue
-				}
+		err := batch.apply(key, func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet {
+			next := current
+			if !existed {
+				next = gcoreSDK.RRSet{Type: key.typ}
+			}
+			if next.TTL == 0 {
+				next.TTL = int(groupRecords[0].RR().TTL.Seconds())
+			}
 
-				rrSet.Records = append(rrSet.Records, gcoreSDK.ResourceRecord{
-					Content: []any{record.RR().Data},
-					Enabled: true,
-				})
+			seen := make(map[string]bool, len(next.Records)+len(groupRecords))
+			for _, rr := range next.Records {
+				seen[rr.ContentToString()] = true
 			}
 
-			if err := cli.UpdateRRSet(ctx, zone, record.RR().Name, recordType, rrSet); err != nil {
-				return nil, err
+			merged := append([]gcoreSDK.ResourceRecord(nil), next.Records...)
+			for _, record := range groupRecords {
+				key := contentKey(record)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				rr := gcoreSDK.ResourceRecord{Content: gcoreContent(record), Enabled: true}
+				if meta := recordMeta(record); meta != nil {
+					applyRecordMeta(&rr, &next, meta)
+				}
+				merged = append(merged, rr)
 			}
-			addedRecords = append(addedRecords, record)
+			next.Records = merged
+
+			return &next
+		})
+		if err != nil {
+			return nil, err
 		}
+
+		addedRecords = append(addedRecords, groupRecords...)
 	}
 
+	p.invalidateZone(zone)
+
 	for i, record := range addedRecords {
 		addedRecords[i] = unqualifyRecordNames(record, zone)
 	}
@@ -232,39 +1023,51 @@ func (p *Provider) AppendRecords(ctx context.Context, zone string, records []lib
 
 // SetRecords sets the records in the zone, either by updating existing records or creating new ones.
 // It returns the updated records.
+//
+// As with AppendRecords, records are grouped by their (name, type) RRSet and
+// each group is replaced with a single read and write; a failed group rolls
+// back any groups already written earlier in the same call (see
+// [BatchError]). As with AppendRecords, a record whose ProviderData is a
+// *RecordMeta has its GCore-specific metadata applied to the RRSet.
 func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	cli := gcoreSDK.NewClient(gcoreSDK.PermanentAPIKeyAuth(p.APIKey))
+	cli := p.client()
 
 	for i, record := range records {
 		records[i] = qualityRecordNames(record, zone)
 	}
 
+	order, groups := groupRRSets(records)
+	batch := newRRSetBatch(ctx, cli, zone)
+
 	var updatedRecords []libdns.Record
 
-	for _, record := range records {
-		rrSet, err := cli.RRSet(ctx, zone, record.RR().Name, record.RR().Type, -1, 0)
-		if err != nil {
-			return nil, err
-		}
+	for _, key := range order {
+		groupRecords := groups[key]
 
-		for _, rr := range rrSet.Records {
-			if rr.ContentToString() == record.RR().Data {
-				continue
+		err := batch.apply(key, func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet {
+			next := gcoreSDK.RRSet{
+				Type:    key.typ,
+				TTL:     int(groupRecords[0].RR().TTL.Seconds()),
+				Records: make([]gcoreSDK.ResourceRecord, 0, len(groupRecords)),
 			}
-
-			rrSet.Records = append(rrSet.Records, gcoreSDK.ResourceRecord{
-				Content: []any{record.RR().Data},
-				Enabled: true,
-			})
-		}
-
-		if err := cli.UpdateRRSet(ctx, zone, record.RR().Name, record.RR().Type, rrSet); err != nil {
+			for _, record := range groupRecords {
+				rr := gcoreSDK.ResourceRecord{Content: gcoreContent(record), Enabled: true}
+				if meta := recordMeta(record); meta != nil {
+					applyRecordMeta(&rr, &next, meta)
+				}
+				next.Records = append(next.Records, rr)
+			}
+			return &next
+		})
+		if err != nil {
 			return nil, err
 		}
 
-		updatedRecords = append(updatedRecords, record)
+		updatedRecords = append(updatedRecords, groupRecords...)
 	}
 
+	p.invalidateZone(zone)
+
 	for i, record := range updatedRecords {
 		updatedRecords[i] = unqualifyRecordNames(record, zone)
 	}
@@ -273,22 +1076,81 @@ func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns
 }
 
 // DeleteRecords deletes the records from the zone. It returns the records that were deleted.
+//
+// Records are grouped by their (name, type) RRSet so that removing several
+// records from the same RRSet costs one read and one write; the RRSet
+// itself is deleted once it has no records left. A failed group rolls back
+// any groups already written earlier in the same call (see [BatchError]).
+//
+// As with AppendRecords, a TXT group named "_acme-challenge[.*]" instead
+// goes through deleteACMEChallengeTXT's detect-and-retry loop, so cleaning
+// up one SAN's challenge value is much less likely to silently undo a
+// concurrent call that's still publishing another SAN's value on the same
+// RRSet (see the caveat on appendACMEChallengeTXT).
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	cli := gcoreSDK.NewClient(gcoreSDK.PermanentAPIKeyAuth(p.APIKey))
+	cli := p.client()
 
 	for i, record := range records {
 		records[i] = qualityRecordNames(record, zone)
 	}
 
+	order, groups := groupRRSets(records)
+	batch := newRRSetBatch(ctx, cli, zone)
+
 	var deletedRecords []libdns.Record
 
-	for _, record := range records {
-		if cli.DeleteRRSetRecord(ctx, zone, record.RR().Name, record.RR().Type, record.RR().Data) != nil {
-			return nil, fmt.Errorf("failed to delete record %v", record)
+	for _, key := range order {
+		groupRecords := groups[key]
+
+		if key.typ == "TXT" && isACMEChallengeName(key.name) {
+			original, existed, err := batch.snapshot(key)
+			if err != nil {
+				return nil, &BatchError{Group: key.String(), Err: err, RollbackErrors: batch.rollback()}
+			}
+			// Tracked before the call, not after: see the matching comment
+			// in AppendRecords.
+			batch.track(key, existed, original)
+			if err := p.deleteACMEChallengeTXT(ctx, cli, zone, key, groupRecords); err != nil {
+				return nil, &BatchError{Group: key.String(), Err: err, RollbackErrors: batch.rollback()}
+			}
+			deletedRecords = append(deletedRecords, groupRecords...)
+			continue
+		}
+
+		err := batch.apply(key, func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet {
+			if !existed {
+				return nil
+			}
+
+			toDelete := make(map[string]bool, len(groupRecords))
+			for _, record := range groupRecords {
+				toDelete[contentKey(record)] = true
+			}
+
+			remaining := make([]gcoreSDK.ResourceRecord, 0, len(current.Records))
+			for _, rr := range current.Records {
+				if toDelete[rr.ContentToString()] {
+					continue
+				}
+				remaining = append(remaining, rr)
+			}
+			if len(remaining) == 0 {
+				return nil
+			}
+
+			next := current
+			next.Records = remaining
+			return &next
+		})
+		if err != nil {
+			return nil, err
 		}
-		deletedRecords = append(deletedRecords, record)
+
+		deletedRecords = append(deletedRecords, groupRecords...)
 	}
 
+	p.invalidateZone(zone)
+
 	for i, record := range deletedRecords {
 		deletedRecords[i] = unqualifyRecordNames(record, zone)
 	}
@@ -296,10 +1158,362 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 	return deletedRecords, nil
 }
 
+// acmeChallengeLabel is the label RFC 8555 §8.4 DNS-01 validation looks
+// for: "_acme-challenge.<domain>".
+const acmeChallengeLabel = "_acme-challenge"
+
+// isACMEChallengeName reports whether name (already zone-qualified) is an
+// ACME DNS-01 challenge name, i.e. its first label is "_acme-challenge".
+func isACMEChallengeName(name string) bool {
+	label, _, _ := strings.Cut(strings.TrimSuffix(name, "."), ".")
+	return label == acmeChallengeLabel
+}
+
+// appendACMEChallengeTXT adds groupRecords, all TXT records named
+// "_acme-challenge[.*]", to zone's TXT RRSet for that name using a
+// detect-and-retry loop in place of rrsetBatch's single read-modify-write.
+// Multi-SAN certificates publish one DNS-01 challenge per name in
+// parallel, but every SAN on the same base domain shares one
+// "_acme-challenge.<domain>" RRSet, so two concurrent AppendRecords calls
+// can each read the RRSet before the other writes, and the later write
+// drops the earlier call's value. A conflict is detected by re-reading the
+// RRSet right after writing and checking that everything this call just
+// wrote (its own values plus whatever it read before writing) is still
+// present; if one went missing, another writer raced this one, and the
+// merge is retried against the fresher read.
+//
+// This narrows the clobbering window but can't close it outright: GCore's
+// API has no conditional-write primitive (no ETag or version to gate
+// UpdateRRSet on), so a third write landing between this call's write and
+// its verifying read would go undetected. In practice this window is one
+// HTTP round trip wide, small enough that retried ACME clients (lego,
+// cert-manager) converge in a handful of attempts, but callers issuing
+// their own retries on top of this should expect occasional conflicts
+// rather than a hard guarantee.
+func (p *Provider) appendACMEChallengeTXT(ctx context.Context, cli *gcoreSDK.Client, zone string, key rrsetKey, groupRecords []libdns.Record) error {
+	maxAttempts, baseDelay, maxDelay := p.retryConfig()
+
+	for attempt := 0; ; attempt++ {
+		current, err := cli.RRSet(ctx, zone, key.name, key.typ, -1, 0)
+		existed := err == nil
+		if err != nil && !strings.Contains(err.Error(), "404: record is not found") {
+			return err
+		}
+
+		prior := make(map[string]bool, len(current.Records))
+		for _, rr := range current.Records {
+			prior[rr.ContentToString()] = true
+		}
+
+		next := current
+		if !existed {
+			next = gcoreSDK.RRSet{Type: key.typ}
+		}
+		if next.TTL == 0 {
+			next.TTL = int(groupRecords[0].RR().TTL.Seconds())
+		}
+
+		merged := append([]gcoreSDK.ResourceRecord(nil), next.Records...)
+		for _, record := range groupRecords {
+			if prior[contentKey(record)] {
+				continue
+			}
+			rr := gcoreSDK.ResourceRecord{Content: gcoreContent(record), Enabled: true}
+			if meta := recordMeta(record); meta != nil {
+				applyRecordMeta(&rr, &next, meta)
+			}
+			merged = append(merged, rr)
+		}
+		next.Records = merged
+
+		if err := cli.UpdateRRSet(ctx, zone, key.name, key.typ, next); err != nil {
+			return err
+		}
+
+		wrote := make(map[string]bool, len(merged))
+		for _, rr := range merged {
+			wrote[rr.ContentToString()] = true
+		}
+
+		conflict, err := rrsetLostRecords(ctx, cli, zone, key, wrote)
+		if err != nil {
+			return err
+		}
+		if !conflict {
+			return nil
+		}
+		if err := waitForRetry(ctx, attempt, maxAttempts, baseDelay, maxDelay, key); err != nil {
+			return err
+		}
+	}
+}
+
+// deleteACMEChallengeTXT removes groupRecords' content from zone's TXT
+// RRSet for key.name using the same detect-and-retry loop as
+// appendACMEChallengeTXT (and the same residual race, see its doc
+// comment), so cleaning up one SAN's challenge after validation is very
+// unlikely to be undone by (or undo) a concurrent call still publishing
+// or deleting a sibling SAN's value on the same RRSet. As on
+// DeleteRecords' default path, the RRSet is only deleted once every
+// sibling record is gone too.
+func (p *Provider) deleteACMEChallengeTXT(ctx context.Context, cli *gcoreSDK.Client, zone string, key rrsetKey, groupRecords []libdns.Record) error {
+	maxAttempts, baseDelay, maxDelay := p.retryConfig()
+
+	toDelete := make(map[string]bool, len(groupRecords))
+	for _, record := range groupRecords {
+		toDelete[contentKey(record)] = true
+	}
+
+	for attempt := 0; ; attempt++ {
+		current, err := cli.RRSet(ctx, zone, key.name, key.typ, -1, 0)
+		if err != nil {
+			if strings.Contains(err.Error(), "404: record is not found") {
+				return nil
+			}
+			return err
+		}
+
+		remaining := make([]gcoreSDK.ResourceRecord, 0, len(current.Records))
+		for _, rr := range current.Records {
+			if !toDelete[rr.ContentToString()] {
+				remaining = append(remaining, rr)
+			}
+		}
+
+		if len(remaining) == len(current.Records) {
+			return nil
+		}
+
+		kept := make(map[string]bool, len(remaining))
+		for _, rr := range remaining {
+			kept[rr.ContentToString()] = true
+		}
+
+		if len(remaining) == 0 {
+			if err := cli.DeleteRRSet(ctx, zone, key.name, key.typ); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		next := current
+		next.Records = remaining
+		if err := cli.UpdateRRSet(ctx, zone, key.name, key.typ, next); err != nil {
+			return err
+		}
+
+		conflict, err := rrsetLostRecords(ctx, cli, zone, key, kept)
+		if err != nil {
+			return err
+		}
+		if !conflict {
+			return nil
+		}
+		if err := waitForRetry(ctx, attempt, maxAttempts, baseDelay, maxDelay, key); err != nil {
+			return err
+		}
+	}
+}
+
+// rrsetLostRecords re-reads key's RRSet and reports whether any content in
+// expected (built from a snapshot taken earlier in the same call) is
+// missing from it, meaning a concurrent writer clobbered this call's write
+// between the write and this read.
+func rrsetLostRecords(ctx context.Context, cli *gcoreSDK.Client, zone string, key rrsetKey, expected map[string]bool) (bool, error) {
+	latest, err := cli.RRSet(ctx, zone, key.name, key.typ, -1, 0)
+	if err != nil {
+		return false, err
+	}
+
+	present := make(map[string]bool, len(latest.Records))
+	for _, rr := range latest.Records {
+		present[rr.ContentToString()] = true
+	}
+
+	for value := range expected {
+		if !present[value] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitForRetry sleeps the backoff delay for attempt before the caller's
+// next detect-and-retry attempt, or returns an error if attempt was the
+// last one allowed or ctx is done first.
+func waitForRetry(ctx context.Context, attempt, maxAttempts int, baseDelay, maxDelay time.Duration, key rrsetKey) error {
+	if attempt+1 >= maxAttempts {
+		return fmt.Errorf("gcore: conflicting concurrent update to %s, giving up after %d attempts", key.String(), maxAttempts)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(retryDelay(attempt, baseDelay, maxDelay, nil)):
+		return nil
+	}
+}
+
+// WaitForPropagation polls zone's authoritative nameservers directly,
+// bypassing GCore's API and any recursive resolver cache, until fqdn's
+// recordType RRSet contains every value in expected or ctx is done. This is
+// the integration point ACME DNS-01 solvers (lego, cert-manager) need:
+// AppendRecords returning successfully only means GCore accepted the
+// write, not that the CA's validator will see it yet, since the CA queries
+// DNS directly rather than GCore's API.
+//
+// recordType is one of "TXT", "A", "AAAA", "CNAME", or "NS"; other types
+// return an error immediately, since this package has no general-purpose
+// DNS client to fall back on.
+func (p *Provider) WaitForPropagation(ctx context.Context, zone, fqdn, recordType string, expected []string) error {
+	switch recordType {
+	case "TXT", "A", "AAAA", "CNAME", "NS":
+	default:
+		return fmt.Errorf("gcore: propagation check not supported for record type %s", recordType)
+	}
+
+	cli := p.client()
+
+	nameservers, err := authoritativeNameservers(ctx, cli, zone)
+	if err != nil {
+		return fmt.Errorf("gcore: looking up authoritative nameservers for %s: %w", zone, err)
+	}
+	if len(nameservers) == 0 {
+		return fmt.Errorf("gcore: zone %s has no NS records", zone)
+	}
+
+	_, baseDelay, maxDelay := p.retryConfig()
+
+	for attempt := 0; ; attempt++ {
+		if allNameserversHaveRecord(ctx, nameservers, fqdn, recordType, expected) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gcore: timed out waiting for %s %s to propagate in %s: %w", recordType, fqdn, zone, ctx.Err())
+		case <-time.After(retryDelay(attempt, baseDelay, maxDelay, nil)):
+		}
+	}
+}
+
+// authoritativeNameservers returns the hostnames in zone's own NS RRSet,
+// the servers an ACME validator actually queries. GCore's API can report a
+// write as successful before every one of them has it.
+func authoritativeNameservers(ctx context.Context, cli *gcoreSDK.Client, zone string) ([]string, error) {
+	rrset, err := cli.RRSet(ctx, zone, zone, "NS", -1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	nameservers := make([]string, 0, len(rrset.Records))
+	for _, rr := range rrset.Records {
+		nameservers = append(nameservers, strings.TrimSuffix(rr.ContentToString(), "."))
+	}
+	return nameservers, nil
+}
+
+// allNameserversHaveRecord reports whether every nameserver in nameservers
+// answers a direct query for fqdn's recordType records with every value in
+// expected. Nameservers are queried concurrently so one slow or
+// unreachable server doesn't multiply this poll round's latency by the
+// zone's NS count.
+func allNameserversHaveRecord(ctx context.Context, nameservers []string, fqdn, recordType string, expected []string) bool {
+	propagated := make([]bool, len(nameservers))
+
+	var wg sync.WaitGroup
+	for i, ns := range nameservers {
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer wg.Done()
+			got, err := lookupDirect(ctx, ns, fqdn, recordType)
+			propagated[i] = err == nil && containsAll(got, expected)
+		}(i, ns)
+	}
+	wg.Wait()
+
+	for _, ok := range propagated {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupDirect queries ns directly for fqdn's recordType records, bypassing
+// the system resolver and any caching it does.
+func lookupDirect(ctx context.Context, ns, fqdn, recordType string) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(ns, "53"))
+		},
+	}
+
+	switch recordType {
+	case "TXT":
+		return resolver.LookupTXT(ctx, fqdn)
+	case "A", "AAAA":
+		return resolver.LookupHost(ctx, fqdn)
+	case "CNAME":
+		target, err := resolver.LookupCNAME(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		return []string{target}, nil
+	case "NS":
+		records, err := resolver.LookupNS(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		hosts := make([]string, len(records))
+		for i, record := range records {
+			hosts[i] = record.Host
+		}
+		return hosts, nil
+	default:
+		return nil, fmt.Errorf("gcore: propagation check not supported for record type %s", recordType)
+	}
+}
+
+// containsAll reports whether got contains every value in expected.
+func containsAll(got, expected []string) bool {
+	set := make(map[string]bool, len(got))
+	for _, v := range got {
+		set[v] = true
+	}
+	for _, v := range expected {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// ListZones returns every zone available under APIKey, for callers that
+// need to discover zones rather than being told them up front (wildcard
+// ACME setups, or tooling that wants to enumerate every zone).
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	cli := p.client()
+
+	gcoreZones, err := cli.AllZones(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+
+	zones := make([]libdns.Zone, len(gcoreZones))
+	for i, gcoreZone := range gcoreZones {
+		zones[i] = libdns.Zone{Name: gcoreZone.Name}
+	}
+
+	return zones, nil
+}
+
 // Interface guards
 var (
 	_ libdns.RecordGetter   = (*Provider)(nil)
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
 )
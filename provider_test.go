@@ -0,0 +1,554 @@
+package gcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gcoreSDK "github.com/G-Core/gcore-dns-sdk-go"
+	"github.com/libdns/libdns"
+)
+
+func TestGcoreContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		record libdns.Record
+		want   []any
+	}{
+		{
+			name:   "CAA",
+			record: libdns.CAA{Name: "@", TTL: time.Minute, Flags: 0, Tag: "issue", Value: "letsencrypt.org"},
+			want:   []any{int64(0), "issue", "letsencrypt.org"},
+		},
+		{
+			name:   "TLSA",
+			record: libdns.RR{Name: "_443._tcp", TTL: time.Minute, Type: "TLSA", Data: "3 1 1 abcdef"},
+			want:   []any{int64(3), int64(1), int64(1), "abcdef"},
+		},
+		{
+			name:   "SSHFP",
+			record: libdns.RR{Name: "@", TTL: time.Minute, Type: "SSHFP", Data: "1 2 abcdef"},
+			want:   []any{int64(1), int64(2), "abcdef"},
+		},
+		{
+			name:   "PTR falls through to opaque data",
+			record: libdns.RR{Name: "1", TTL: time.Minute, Type: "PTR", Data: "host.example.com."},
+			want:   []any{"host.example.com."},
+		},
+		{
+			name:   "HTTPS",
+			record: libdns.ServiceBinding{Name: "@", TTL: time.Minute, Scheme: "https", Priority: 1, Target: ".", Params: libdns.SvcParams{"alpn": []string{"h2", "h3"}}},
+			want:   []any{uint16(1), ".", []any{"alpn", "h2", "h3"}},
+		},
+		{
+			name:   "SVCB",
+			record: libdns.ServiceBinding{Name: "@", TTL: time.Minute, Scheme: "dot", Priority: 1, Target: ".", Params: libdns.SvcParams{"alpn": []string{"dot"}}},
+			want:   []any{uint16(1), ".", []any{"alpn", "dot"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gcoreContent(tt.record)
+			if len(got) != len(tt.want) {
+				t.Fatalf("gcoreContent() = %#v, want %#v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Fatalf("gcoreContent()[%d] = %#v, want %#v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTLSAContentInvalid(t *testing.T) {
+	got := tlsaContent("not-a-tlsa-record")
+	want := []any{"not-a-tlsa-record"}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("tlsaContent() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSSHFPContentInvalid(t *testing.T) {
+	got := sshfpContent("not-an-sshfp-record")
+	want := []any{"not-an-sshfp-record"}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("sshfpContent() = %#v, want %#v", got, want)
+	}
+}
+
+func TestContentKeyMatchesGCoreFormatting(t *testing.T) {
+	caa := libdns.CAA{Name: "@", TTL: time.Minute, Flags: 0, Tag: "issue", Value: "letsencrypt.org"}
+	if got, want := contentKey(caa), "0 issue letsencrypt.org"; got != want {
+		t.Errorf("contentKey(CAA) = %q, want %q", got, want)
+	}
+
+	tlsa := libdns.RR{Name: "_443._tcp", TTL: time.Minute, Type: "TLSA", Data: "3 1 1 abcdef"}
+	if got, want := contentKey(tlsa), "3 1 1 abcdef"; got != want {
+		t.Errorf("contentKey(TLSA) = %q, want %q", got, want)
+	}
+
+	svcb := libdns.ServiceBinding{Name: "@", TTL: time.Minute, Scheme: "dot", Priority: 1, Target: ".", Params: libdns.SvcParams{"alpn": []string{"dot"}}}
+	if got, want := contentKey(svcb), `1 . alpn="dot"`; got != want {
+		t.Errorf("contentKey(SVCB) = %q, want %q", got, want)
+	}
+}
+
+func TestQualifyRecordNamesPreservesProviderDataForCAA(t *testing.T) {
+	caa := libdns.CAA{Name: "www", TTL: time.Minute, Flags: 0, Tag: "issue", Value: "letsencrypt.org", ProviderData: &RecordMeta{Weight: intPtr(10)}}
+
+	qualified := qualityRecordNames(caa, "example.com")
+	qualifiedCAA, ok := qualified.(libdns.CAA)
+	if !ok {
+		t.Fatalf("qualityRecordNames(CAA) returned %T, want libdns.CAA", qualified)
+	}
+	if qualifiedCAA.Name != "www.example.com" {
+		t.Errorf("qualityRecordNames(CAA).Name = %q, want %q", qualifiedCAA.Name, "www.example.com")
+	}
+	meta, ok := qualifiedCAA.ProviderData.(*RecordMeta)
+	if !ok || meta.Weight == nil || *meta.Weight != 10 {
+		t.Errorf("qualityRecordNames(CAA).ProviderData = %#v, want RecordMeta with Weight 10", qualifiedCAA.ProviderData)
+	}
+}
+
+func TestUnqualifyRecordNamesGenericRR(t *testing.T) {
+	ptr := libdns.RR{Name: "1.0.0.127.in-addr.arpa.example.com.", TTL: time.Minute, Type: "PTR", Data: "host.example.com."}
+
+	unqualified := unqualifyRecordNames(ptr, "example.com")
+	unqualifiedRR, ok := unqualified.(libdns.RR)
+	if !ok {
+		t.Fatalf("unqualifyRecordNames(PTR) returned %T, want libdns.RR", unqualified)
+	}
+	if unqualifiedRR.Name != "1.0.0.127.in-addr.arpa" {
+		t.Errorf("unqualifyRecordNames(PTR).Name = %q, want %q", unqualifiedRR.Name, "1.0.0.127.in-addr.arpa")
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestGroupRRSets(t *testing.T) {
+	records := []libdns.Record{
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.1"},
+		libdns.RR{Name: "@", TTL: time.Minute, Type: "TXT", Data: "first"},
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.2"},
+		libdns.RR{Name: "@", TTL: time.Minute, Type: "TXT", Data: "second"},
+	}
+
+	order, groups := groupRRSets(records)
+
+	wantOrder := []rrsetKey{{name: "www", typ: "A"}, {name: "@", typ: "TXT"}}
+	if len(order) != len(wantOrder) || order[0] != wantOrder[0] || order[1] != wantOrder[1] {
+		t.Fatalf("groupRRSets() order = %#v, want %#v", order, wantOrder)
+	}
+
+	if got := len(groups[rrsetKey{name: "www", typ: "A"}]); got != 2 {
+		t.Errorf("groupRRSets() groups[www A] has %d records, want 2", got)
+	}
+	if got := len(groups[rrsetKey{name: "@", typ: "TXT"}]); got != 2 {
+		t.Errorf("groupRRSets() groups[@ TXT] has %d records, want 2", got)
+	}
+}
+
+// fakeRRSetServer is an in-memory stand-in for the GCore RRSet endpoints
+// (GET/PUT/DELETE .../zones/{zone}/{name}/{type}, with or without gcoreSDK's
+// "/dns" base path prefix) used to exercise rrsetBatch and the ACME helpers
+// without a real API. failOn, if set, makes every write to that RRSet
+// key fail with a 500 instead of being applied. onPUT, if set, lets a test
+// replace what a write actually persists (e.g. to simulate a concurrent
+// writer clobbering it) instead of persisting the write as given.
+type fakeRRSetServer struct {
+	mu     sync.Mutex
+	rrsets map[string]gcoreSDK.RRSet
+	failOn string
+	onPUT  func(key string, written gcoreSDK.RRSet) gcoreSDK.RRSet
+}
+
+func newFakeRRSetServer(seed map[string]gcoreSDK.RRSet) *fakeRRSetServer {
+	rrsets := make(map[string]gcoreSDK.RRSet, len(seed))
+	for k, v := range seed {
+		rrsets[k] = v
+	}
+	return &fakeRRSetServer{rrsets: rrsets}
+}
+
+func (s *fakeRRSetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	// ends in .../zones/{zone}/{name}/{type}, optionally under a base path.
+	if len(parts) < 3 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	n := len(parts)
+	key := parts[n-1] + " " + parts[n-2]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		rrset, ok := s.rrsets[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(gcoreSDK.APIError{Message: "record is not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(rrset)
+	case http.MethodPut:
+		if s.failOn == key {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(gcoreSDK.APIError{Message: "internal error"})
+			return
+		}
+		var rrset gcoreSDK.RRSet
+		_ = json.NewDecoder(r.Body).Decode(&rrset)
+		if s.onPUT != nil {
+			rrset = s.onPUT(key, rrset)
+		}
+		s.rrsets[key] = rrset
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if s.failOn == key {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(gcoreSDK.APIError{Message: "internal error"})
+			return
+		}
+		delete(s.rrsets, key)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestClient(t *testing.T, server *httptest.Server) *gcoreSDK.Client {
+	t.Helper()
+	cli := gcoreSDK.NewClient(gcoreSDK.PermanentAPIKeyAuth("test"))
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+	cli.BaseURL = baseURL
+	cli.HTTPClient = server.Client()
+	return cli
+}
+
+func TestRRSetBatchRollback(t *testing.T) {
+	wwwKey := rrsetKey{name: "www", typ: "A"}
+	mailKey := rrsetKey{name: "mail", typ: "A"}
+
+	fake := newFakeRRSetServer(map[string]gcoreSDK.RRSet{
+		wwwKey.String():  {Type: "A", TTL: 60, Records: []gcoreSDK.ResourceRecord{{Content: []any{"192.0.2.1"}, Enabled: true}}},
+		mailKey.String(): {Type: "A", TTL: 60, Records: []gcoreSDK.ResourceRecord{{Content: []any{"192.0.2.2"}, Enabled: true}}},
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.failOn = mailKey.String()
+
+	cli := newTestClient(t, server)
+	batch := newRRSetBatch(context.Background(), cli, "example.com")
+
+	if err := batch.apply(wwwKey, func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet {
+		current.Records = append(current.Records, gcoreSDK.ResourceRecord{Content: []any{"192.0.2.9"}, Enabled: true})
+		return &current
+	}); err != nil {
+		t.Fatalf("apply(www) = %v, want nil", err)
+	}
+
+	err := batch.apply(mailKey, func(current gcoreSDK.RRSet, existed bool) *gcoreSDK.RRSet {
+		current.Records = append(current.Records, gcoreSDK.ResourceRecord{Content: []any{"192.0.2.10"}, Enabled: true})
+		return &current
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("apply(mail) error = %v, want *BatchError", err)
+	}
+	if batchErr.Group != mailKey.String() {
+		t.Errorf("BatchError.Group = %q, want %q", batchErr.Group, mailKey.String())
+	}
+	if len(batchErr.RollbackErrors) != 0 {
+		t.Errorf("BatchError.RollbackErrors = %v, want none (rollback of www should have succeeded)", batchErr.RollbackErrors)
+	}
+	if batchErr.Unwrap() == nil {
+		t.Error("BatchError.Unwrap() = nil, want the underlying write error")
+	}
+
+	fake.mu.Lock()
+	got := fake.rrsets[wwwKey.String()]
+	fake.mu.Unlock()
+	if len(got.Records) != 1 || got.Records[0].Content[0] != "192.0.2.1" {
+		t.Errorf("after rollback, rrsets[www] = %#v, want only the original record restored", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"not-a-duration", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryDelayHonorsRetryAfterHeader(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"3"}}}
+
+	got := retryDelay(0, time.Second, time.Minute, resp)
+	if got != 3*time.Second {
+		t.Errorf("retryDelay() = %v, want %v (from Retry-After)", got, 3*time.Second)
+	}
+}
+
+func TestRetryDelayBackoffIsCapped(t *testing.T) {
+	baseDelay, maxDelay := 100*time.Millisecond, time.Second
+
+	for attempt := 0; attempt < 10; attempt++ {
+		got := retryDelay(attempt, baseDelay, maxDelay, nil)
+		if got > maxDelay {
+			t.Errorf("retryDelay(attempt=%d) = %v, want <= %v", attempt, got, maxDelay)
+		}
+		if got < 0 {
+			t.Errorf("retryDelay(attempt=%d) = %v, want >= 0", attempt, got)
+		}
+	}
+}
+
+func TestRetryTransportRetriesTransientFailures(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		next:       server.Client().Transport,
+		maxRetries: 3,
+		baseDelay:  time.Millisecond,
+		maxDelay:   time.Millisecond,
+	}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("client.Get() = %v, want nil error", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("resp.StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if attempts != 3 {
+		t.Errorf("server saw %d attempts, want 3 (2 failures then a success)", attempts)
+	}
+}
+
+func TestRetryTransportGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		next:       server.Client().Transport,
+		maxRetries: 2,
+		baseDelay:  time.Millisecond,
+		maxDelay:   time.Millisecond,
+	}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("client.Get() = %v, want nil error", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("resp.StatusCode = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if attempts != 3 {
+		t.Errorf("server saw %d attempts, want 3 (the initial try plus 2 retries)", attempts)
+	}
+}
+
+// redirectTransport rewrites every request's scheme and host to target's
+// (a test server) before sending it, so a client hardcoded to a real API's
+// base URL (as gcoreSDK.Client is) can be driven against an httptest.Server
+// without Provider needing to expose a way to override it.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProviderGetRecordsEndToEnd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/dns/v2/zones/example.com"):
+			_ = json.NewEncoder(w).Encode(gcoreSDK.Zone{
+				Name:    "example.com",
+				Records: []gcoreSDK.ZoneRecord{{Name: "www.example.com", Type: "A"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/dns/v2/zones/example.com/www.example.com/A"):
+			_ = json.NewEncoder(w).Encode(gcoreSDK.RRSet{
+				Type:    "A",
+				TTL:     60,
+				Records: []gcoreSDK.ResourceRecord{{Content: []any{"192.0.2.1"}, Enabled: true}},
+			})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+
+	p := &Provider{
+		APIKey:     "test",
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	}
+
+	records, err := p.GetRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("GetRecords() = %v, want nil error", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("GetRecords() returned %d records, want 1: %#v", len(records), records)
+	}
+	rr := records[0].RR()
+	if rr.Name != "www" || rr.Type != "A" || rr.Data != "192.0.2.1" {
+		t.Errorf("GetRecords()[0] = %#v, want name=www type=A data=192.0.2.1", rr)
+	}
+}
+
+// TestAppendRecordsACMERollsBackOnConflictGiveUp simulates a concurrent
+// writer clobbering an ACME challenge TXT write between appendACMEChallengeTXT's
+// write and its verifying read: the fake server silently replaces the
+// content it was just given with a value that doesn't include it, so the
+// loop's conflict check fires. With MaxRetries set to 1, the loop gives up
+// immediately instead of retrying, and AppendRecords must roll the RRSet
+// back to what it was before this call rather than leaving the clobbered
+// value in place.
+func TestAppendRecordsACMERollsBackOnConflictGiveUp(t *testing.T) {
+	const zone = "example.com"
+	acmeKey := rrsetKey{name: "_acme-challenge.example.com", typ: "TXT"}
+
+	original := gcoreSDK.RRSet{
+		Type:    "TXT",
+		TTL:     60,
+		Records: []gcoreSDK.ResourceRecord{{Content: []any{"orig-value"}, Enabled: true}},
+	}
+	clobbered := gcoreSDK.RRSet{
+		Type:    "TXT",
+		TTL:     60,
+		Records: []gcoreSDK.ResourceRecord{{Content: []any{"other-writer-value"}, Enabled: true}},
+	}
+
+	fake := newFakeRRSetServer(map[string]gcoreSDK.RRSet{acmeKey.String(): original})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	var puts int
+	fake.onPUT = func(key string, written gcoreSDK.RRSet) gcoreSDK.RRSet {
+		puts++
+		if key == acmeKey.String() && puts == 1 {
+			return clobbered
+		}
+		return written
+	}
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+
+	p := &Provider{
+		APIKey:     "test",
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+		MaxRetries: 1,
+	}
+
+	_, err = p.AppendRecords(context.Background(), zone, []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "new-value"},
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("AppendRecords() error = %v, want *BatchError", err)
+	}
+	if len(batchErr.RollbackErrors) != 0 {
+		t.Fatalf("BatchError.RollbackErrors = %v, want none", batchErr.RollbackErrors)
+	}
+
+	fake.mu.Lock()
+	got := fake.rrsets[acmeKey.String()]
+	fake.mu.Unlock()
+	if len(got.Records) != 1 || got.Records[0].Content[0] != "orig-value" {
+		t.Errorf("after rollback, rrsets[_acme-challenge] = %#v, want only the pre-call original restored", got)
+	}
+}
+
+func TestIsACMEChallengeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"_acme-challenge.example.com.", true},
+		{"_acme-challenge.www.example.com.", true},
+		{"_acme-challenge", true},
+		{"www.example.com.", false},
+		{"_acme-challenges.example.com.", false},
+	}
+
+	for _, tt := range tests {
+		if got := isACMEChallengeName(tt.name); got != tt.want {
+			t.Errorf("isACMEChallengeName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContainsAll(t *testing.T) {
+	got := []string{"a", "b", "c"}
+
+	if !containsAll(got, []string{"a", "c"}) {
+		t.Error("containsAll() = false, want true for a subset of got")
+	}
+	if containsAll(got, []string{"a", "d"}) {
+		t.Error("containsAll() = true, want false when expected has a value got lacks")
+	}
+	if !containsAll(got, nil) {
+		t.Error("containsAll() = false, want true for an empty expected set")
+	}
+}